@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/index"
+)
+
+func TestParseBucketFlag(t *testing.T) {
+	tests := []struct {
+		raw          string
+		wantStrategy Bucketing
+		wantBounds   []int64
+		wantErr      bool
+	}{
+		{raw: "", wantStrategy: Exact},
+		{raw: "exact", wantStrategy: Exact},
+		{raw: "pow2", wantStrategy: PowerOfTwo},
+		{raw: "1024,4096,1048576", wantStrategy: Custom, wantBounds: []int64{1024, 4096, 1048576}},
+		{raw: "4096,1024", wantStrategy: Custom, wantBounds: []int64{1024, 4096}},
+		{raw: "not-a-number", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		strategy, bounds, err := parseBucketFlag(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseBucketFlag(%q): expected an error, got none", tt.raw)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseBucketFlag(%q): %v", tt.raw, err)
+			continue
+		}
+		if strategy != tt.wantStrategy {
+			t.Errorf("parseBucketFlag(%q): got strategy %v, want %v", tt.raw, strategy, tt.wantStrategy)
+		}
+		if len(bounds) != len(tt.wantBounds) {
+			t.Fatalf("parseBucketFlag(%q): got bounds %v, want %v", tt.raw, bounds, tt.wantBounds)
+		}
+		for i := range bounds {
+			if bounds[i] != tt.wantBounds[i] {
+				t.Errorf("parseBucketFlag(%q): got bounds %v, want %v", tt.raw, bounds, tt.wantBounds)
+			}
+		}
+	}
+}
+
+func TestBuildDistributionPowerOfTwo(t *testing.T) {
+	idx := index.New("/tmp")
+	sizes := []int64{512, 1500, 1500, 3000000}
+	for i, size := range sizes {
+		path := "/tmp/file" + string(rune('a'+i))
+		idx.Entries[path] = index.Entry{Path: path, Size: size}
+	}
+
+	buckets := BuildDistribution(idx, PowerOfTwo, nil)
+
+	var total int64
+	for _, b := range buckets {
+		total += b.Count
+	}
+	if total != int64(len(sizes)) {
+		t.Fatalf("got %d total bucketed files, want %d", total, len(sizes))
+	}
+
+	for _, b := range buckets {
+		if b.Label == "" {
+			t.Errorf("bucket %+v has an empty label", b)
+		}
+		if b.Min > b.Median || b.Median > b.Max {
+			t.Errorf("bucket %+v has Min/Median/Max out of order", b)
+		}
+	}
+}
+
+func TestBuildDistributionCustomBoundsAndMedian(t *testing.T) {
+	idx := index.New("/tmp")
+	for i, size := range []int64{10, 20, 30} {
+		path := "/tmp/file" + string(rune('a'+i))
+		idx.Entries[path] = index.Entry{Path: path, Size: size}
+	}
+
+	buckets := BuildDistribution(idx, Custom, []int64{100})
+	if len(buckets) != 1 {
+		t.Fatalf("got %d buckets, want 1", len(buckets))
+	}
+
+	b := buckets[0]
+	if b.Count != 3 {
+		t.Errorf("got count %d, want 3", b.Count)
+	}
+	if b.Total != 60 {
+		t.Errorf("got total %d, want 60", b.Total)
+	}
+	if b.Median != 20 {
+		t.Errorf("got median %d, want 20 (odd-length median is the middle element)", b.Median)
+	}
+	if b.Label != "0 - 100 B" {
+		t.Errorf("got label %q, want %q", b.Label, "0 - 100 B")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/index"
+	"github.com/Starkey-RU/FileSizerAnalyzer/report"
+)
+
+// BuildExtensionBreakdown aggregates every entry in idx by its
+// lower-cased file extension, returning one report.CategoryItem per
+// extension sorted by total bytes, descending. Files with no extension
+// are grouped under "(none)".
+func BuildExtensionBreakdown(idx *index.Index) []report.CategoryItem {
+	agg := make(map[string]*report.CategoryItem)
+	for _, e := range idx.Entries {
+		key := strings.ToLower(filepath.Ext(e.Path))
+		if key == "" {
+			key = "(none)"
+		}
+		item, ok := agg[key]
+		if !ok {
+			item = &report.CategoryItem{Key: key}
+			agg[key] = item
+		}
+		item.Count++
+		item.TotalSize += e.Size
+	}
+	return sortedCategories(agg)
+}
+
+// BuildMIMEBreakdown aggregates every entry in idx by its MIME type,
+// detected from the first 512 bytes of each file via
+// http.DetectContentType. It reads every file, so it's only worth
+// calling behind a flag such as -sniff. concurrency bounds how many
+// files are sniffed at once; <= 0 selects runtime.NumCPU().
+func BuildMIMEBreakdown(idx *index.Index, concurrency int) []report.CategoryItem {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	type sniffResult struct {
+		mime string
+		size int64
+	}
+
+	paths := make(chan string, len(idx.Entries))
+	results := make(chan sniffResult, len(idx.Entries))
+
+	var workers sync.WaitGroup
+	workers.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for path := range paths {
+				results <- sniffResult{mime: sniffMIME(path), size: idx.Entries[path].Size}
+			}
+		}()
+	}
+
+	for path := range idx.Entries {
+		paths <- path
+	}
+	close(paths)
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	agg := make(map[string]*report.CategoryItem)
+	for r := range results {
+		item, ok := agg[r.mime]
+		if !ok {
+			item = &report.CategoryItem{Key: r.mime}
+			agg[r.mime] = item
+		}
+		item.Count++
+		item.TotalSize += r.size
+	}
+	return sortedCategories(agg)
+}
+
+// sniffMIME reads the first 512 bytes of path and classifies them with
+// http.DetectContentType, falling back to "unknown" if the file can't
+// be opened or read.
+func sniffMIME(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return "unknown"
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := f.Read(buf)
+	if err != nil && n == 0 {
+		return "unknown"
+	}
+	return http.DetectContentType(buf[:n])
+}
+
+func sortedCategories(agg map[string]*report.CategoryItem) []report.CategoryItem {
+	items := make([]report.CategoryItem, 0, len(agg))
+	for _, item := range agg {
+		items = append(items, *item)
+	}
+	sort.Sort(report.ByTotalSize(items))
+	return items
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/index"
+)
+
+func TestBuildExtensionBreakdown(t *testing.T) {
+	idx := index.New("/tmp")
+	idx.Entries["/tmp/a.go"] = index.Entry{Path: "/tmp/a.go", Size: 100}
+	idx.Entries["/tmp/b.GO"] = index.Entry{Path: "/tmp/b.GO", Size: 200}
+	idx.Entries["/tmp/c.txt"] = index.Entry{Path: "/tmp/c.txt", Size: 50}
+	idx.Entries["/tmp/noext"] = index.Entry{Path: "/tmp/noext", Size: 10}
+
+	items := BuildExtensionBreakdown(idx)
+
+	byKey := make(map[string]int64)
+	for _, item := range items {
+		byKey[item.Key] = item.TotalSize
+	}
+
+	if got := byKey[".go"]; got != 300 {
+		t.Errorf(".go extension is case-insensitive: got total %d, want 300", got)
+	}
+	if got := byKey["(none)"]; got != 10 {
+		t.Errorf("extensionless files should group under \"(none)\": got %d, want 10", got)
+	}
+
+	// sortedCategories orders by total bytes, descending.
+	for i := 1; i < len(items); i++ {
+		if items[i-1].TotalSize < items[i].TotalSize {
+			t.Errorf("items not sorted by TotalSize descending: %+v", items)
+		}
+	}
+}
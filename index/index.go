@@ -0,0 +1,157 @@
+// Package index persists the result of a directory scan so that a
+// subsequent run can skip re-stat'ing files that have not changed,
+// instead of always paying for a full filepath.Walk.
+package index
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/pkg/scan"
+)
+
+// Entry describes a single scanned file as stored in the persistent index.
+type Entry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Inode   uint64
+}
+
+// Index is a persisted snapshot of a directory tree's file metadata,
+// keyed by path so Rescan can cheaply tell which entries are stale.
+type Index struct {
+	Root    string
+	Entries map[string]Entry
+}
+
+// New returns an empty Index rooted at root.
+func New(root string) *Index {
+	return &Index{Root: root, Entries: make(map[string]Entry)}
+}
+
+// Load reads a previously saved Index from path. A missing file is not
+// treated as an error: it yields an empty index so the first run behaves
+// like a full scan.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return New(""), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("index: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	idx := &Index{}
+	if err := gob.NewDecoder(f).Decode(idx); err != nil {
+		return nil, fmt.Errorf("index: decode %s: %w", path, err)
+	}
+	if idx.Entries == nil {
+		idx.Entries = make(map[string]Entry)
+	}
+	return idx, nil
+}
+
+// Save persists the index to path as a gob stream.
+func (idx *Index) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("index: create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := gob.NewEncoder(f).Encode(idx); err != nil {
+		return fmt.Errorf("index: encode %s: %w", path, err)
+	}
+	return nil
+}
+
+// Histogram returns the size-in-KB -> file-count aggregate the Excel
+// report is built from, derived from the current entries.
+func (idx *Index) Histogram() map[int64]int64 {
+	hist := make(map[int64]int64, len(idx.Entries))
+	for _, e := range idx.Entries {
+		hist[e.Size/1024]++
+	}
+	return hist
+}
+
+// Rescan walks root with a worker pool bounded by concurrency (<= 0
+// selects runtime.NumCPU(), see scan.Options). Every file is still
+// stat'd once by scan.Walk; for a file whose size and mtime match its
+// entry in prev, that stored entry is kept as is. It returns a new Index
+// reflecting the tree as it stands now; prev is left untouched.
+func Rescan(root string, prev *Index, concurrency int) (*Index, error) {
+	items, err := scan.Walk(root, scan.Options{Concurrency: concurrency})
+	if err != nil {
+		return nil, fmt.Errorf("index: rescan %s: %w", root, err)
+	}
+
+	next := New(root)
+	for item := range items {
+		if old, ok := prev.Entries[item.Path]; ok && old.Size == item.Size && old.ModTime.Equal(item.ModTime) {
+			next.Entries[item.Path] = old
+			continue
+		}
+
+		// scan.StatItem now carries the inode scan.Walk already picked up
+		// off the same DirEntry.Info() call, so new or modified files
+		// don't need a second Lstat just to recover it.
+		next.Entries[item.Path] = Entry{
+			Path:    item.Path,
+			Size:    item.Size,
+			ModTime: item.ModTime,
+			Inode:   item.Inode,
+		}
+	}
+	return next, nil
+}
+
+// ApplyDiff updates idx in place from a zfs-diff style change list: lines
+// of the form "+ path" (added), "- path" (removed) or "M path" (modified).
+// This lets a caller skip the full filepath.Walk entirely when it already
+// knows what changed. A rename shows up as a delete followed by an add;
+// a modify simply re-stats the file and updates its bucket in place.
+func ApplyDiff(idx *Index, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		op, path, ok := strings.Cut(line, " ")
+		if !ok {
+			return fmt.Errorf("index: malformed diff line %q", line)
+		}
+		path = strings.TrimSpace(path)
+
+		switch op {
+		case "-":
+			delete(idx.Entries, path)
+		case "+", "M":
+			info, err := os.Lstat(path)
+			if err != nil {
+				return fmt.Errorf("index: stat %s: %w", path, err)
+			}
+			idx.Entries[path] = newEntry(path, info)
+		default:
+			return fmt.Errorf("index: unknown diff op %q", op)
+		}
+	}
+	return scanner.Err()
+}
+
+func newEntry(path string, f os.FileInfo) Entry {
+	return Entry{
+		Path:    path,
+		Size:    f.Size(),
+		ModTime: f.ModTime(),
+		Inode:   inodeOf(f),
+	}
+}
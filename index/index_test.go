@@ -0,0 +1,115 @@
+package index_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/index"
+)
+
+func mustWriteFile(t *testing.T, dir, name string, size int) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	return path
+}
+
+// TestApplyDiffRenameIsDeleteThenAdd covers the "rename = delete+add"
+// merge semantics: a "- old" line followed by a "+ new" line should
+// remove the old entry and add the new one under its own path.
+func TestApplyDiffRenameIsDeleteThenAdd(t *testing.T) {
+	dir := t.TempDir()
+	newPath := mustWriteFile(t, dir, "renamed.txt", 2048)
+	oldPath := filepath.Join(dir, "original.txt")
+
+	idx := index.New(dir)
+	idx.Entries[oldPath] = index.Entry{Path: oldPath, Size: 2048, ModTime: time.Now()}
+
+	diff := strings.NewReader("- " + oldPath + "\n+ " + newPath + "\n")
+	if err := index.ApplyDiff(idx, diff); err != nil {
+		t.Fatalf("ApplyDiff: %v", err)
+	}
+
+	if _, ok := idx.Entries[oldPath]; ok {
+		t.Errorf("expected %s to be removed from the index", oldPath)
+	}
+	entry, ok := idx.Entries[newPath]
+	if !ok {
+		t.Fatalf("expected %s to be present in the index", newPath)
+	}
+	if entry.Size != 2048 {
+		t.Errorf("got size %d, want 2048", entry.Size)
+	}
+
+	hist := idx.Histogram()
+	if got := hist[2048/1024]; got != 1 {
+		t.Errorf("got histogram[%d] = %d, want 1", 2048/1024, got)
+	}
+}
+
+// TestApplyDiffModifyUpdatesSizeBucket covers the "modify updates the
+// size bucket" merge semantics: an "M path" line should re-stat the
+// file and move it out of its old KB bucket and into its new one.
+func TestApplyDiffModifyUpdatesSizeBucket(t *testing.T) {
+	dir := t.TempDir()
+	path := mustWriteFile(t, dir, "grows.txt", 1024)
+
+	idx := index.New(dir)
+	idx.Entries[path] = index.Entry{Path: path, Size: 1024, ModTime: time.Now()}
+
+	if err := os.WriteFile(path, make([]byte, 4096), 0o644); err != nil {
+		t.Fatalf("rewrite %s: %v", path, err)
+	}
+
+	diff := strings.NewReader("M " + path + "\n")
+	if err := index.ApplyDiff(idx, diff); err != nil {
+		t.Fatalf("ApplyDiff: %v", err)
+	}
+
+	entry := idx.Entries[path]
+	if entry.Size != 4096 {
+		t.Errorf("got size %d, want 4096", entry.Size)
+	}
+
+	hist := idx.Histogram()
+	if got := hist[1024/1024]; got != 0 {
+		t.Errorf("old bucket still has %d entries, want 0", got)
+	}
+	if got := hist[4096/1024]; got != 1 {
+		t.Errorf("new bucket has %d entries, want 1", got)
+	}
+}
+
+// TestApplyDiffDeleteDecrementsCount covers the "delete decrements
+// count" merge semantics: a "- path" line should remove exactly that
+// entry, leaving its size bucket's count one lower.
+func TestApplyDiffDeleteDecrementsCount(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.bin")
+	pathB := filepath.Join(dir, "b.bin")
+
+	idx := index.New(dir)
+	idx.Entries[pathA] = index.Entry{Path: pathA, Size: 2048, ModTime: time.Now()}
+	idx.Entries[pathB] = index.Entry{Path: pathB, Size: 2048, ModTime: time.Now()}
+
+	if got := idx.Histogram()[2048/1024]; got != 2 {
+		t.Fatalf("got histogram[%d] = %d, want 2", 2048/1024, got)
+	}
+
+	diff := strings.NewReader("- " + pathA + "\n")
+	if err := index.ApplyDiff(idx, diff); err != nil {
+		t.Fatalf("ApplyDiff: %v", err)
+	}
+
+	if _, ok := idx.Entries[pathA]; ok {
+		t.Errorf("expected %s to be removed from the index", pathA)
+	}
+	if got := idx.Histogram()[2048/1024]; got != 1 {
+		t.Errorf("got histogram[%d] = %d, want 1", 2048/1024, got)
+	}
+}
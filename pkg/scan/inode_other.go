@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package scan
+
+import "os"
+
+// inodeOf has no portable equivalent outside *nix filesystems, so
+// platforms without one simply report 0.
+func inodeOf(f os.FileInfo) uint64 {
+	return 0
+}
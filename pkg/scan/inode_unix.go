@@ -0,0 +1,17 @@
+//go:build linux || darwin
+
+package scan
+
+import (
+	"os"
+	"syscall"
+)
+
+// inodeOf extracts the inode number from a *nix os.FileInfo, returning 0
+// if the underlying Sys() value isn't a syscall.Stat_t.
+func inodeOf(f os.FileInfo) uint64 {
+	if st, ok := f.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino)
+	}
+	return 0
+}
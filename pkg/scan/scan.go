@@ -0,0 +1,169 @@
+// Package scan walks a directory tree with a bounded pool of worker
+// goroutines, streaming a StatItem per non-directory entry instead of building
+// an in-memory aggregate itself. Callers own the aggregation step, which
+// keeps the package reusable outside main.
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Options controls how Walk traverses a directory tree.
+type Options struct {
+	// Concurrency is the number of worker goroutines reading directories
+	// and stat'ing entries concurrently. A value <= 0 selects
+	// runtime.NumCPU().
+	Concurrency int
+	// MaxOpenFiles bounds how many directories may be open at once, via
+	// a semaphore, so Walk doesn't exhaust the process's file
+	// descriptors on very wide trees. A value <= 0 selects
+	// Concurrency * 4.
+	MaxOpenFiles int
+}
+
+// StatItem is a single non-directory entry (regular file, symlink or
+// special file) discovered while walking.
+type StatItem struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Inode   uint64
+}
+
+// Walk streams a StatItem for every non-directory entry under root. It replaces
+// the old one-goroutine-per-subdirectory fan-out with a worker pool
+// bounded by opts.Concurrency: workers pull pending directories off a
+// shared dirQueue, list them with os.ReadDir, and resolve each entry with
+// DirEntry.Info() so directories are never stat'd twice. StatItem.Inode
+// is populated from that same Info() call, so a caller like
+// index.Rescan never needs a second stat just to recover it. The
+// returned channel is closed once every directory under root has been
+// processed.
+func Walk(root string, opts Options) (<-chan StatItem, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+	if opts.MaxOpenFiles <= 0 {
+		opts.MaxOpenFiles = opts.Concurrency * 4
+	}
+
+	if _, err := os.Lstat(root); err != nil {
+		return nil, err
+	}
+
+	out := make(chan StatItem, 1024)
+	fds := make(chan struct{}, opts.MaxOpenFiles)
+
+	queue := newDirQueue()
+	var pending sync.WaitGroup
+	pending.Add(1)
+	queue.push(root)
+
+	var workers sync.WaitGroup
+	workers.Add(opts.Concurrency)
+	for i := 0; i < opts.Concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for {
+				dir, ok := queue.pop()
+				if !ok {
+					return
+				}
+				walkOne(dir, queue, out, fds, &pending)
+				pending.Done()
+			}
+		}()
+	}
+
+	go func() {
+		pending.Wait()
+		queue.close()
+		workers.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// walkOne lists a single directory, pushing subdirectories back onto
+// queue for another worker to pick up and emitting a StatItem for every
+// other entry it finds. Symlinks are not followed; they are reported
+// with their own Lstat size.
+func walkOne(dir string, queue *dirQueue, out chan<- StatItem, fds chan struct{}, pending *sync.WaitGroup) {
+	fds <- struct{}{}
+	entries, err := os.ReadDir(dir)
+	<-fds
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			pending.Add(1)
+			queue.push(path)
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		out <- StatItem{Path: path, Size: info.Size(), ModTime: info.ModTime(), Inode: inodeOf(info)}
+	}
+}
+
+// dirQueue is an unbounded LIFO stack of pending directories. A plain buffered
+// channel shared between producers and consumers would self-deadlock
+// here: walkOne is called from inside a worker's own receive loop, so if
+// every worker is blocked trying to push a subdirectory into a full
+// channel, nobody is left to drain it. dirQueue's push never blocks, so
+// a worker can always enqueue what it finds and move on.
+type dirQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []string
+	closed bool
+}
+
+func newDirQueue() *dirQueue {
+	q := &dirQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *dirQueue) push(dir string) {
+	q.mu.Lock()
+	q.items = append(q.items, dir)
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// pop blocks until a directory is available or the queue is closed, in
+// which case it returns ok == false.
+func (q *dirQueue) pop() (dir string, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return "", false
+	}
+	dir = q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return dir, true
+}
+
+// close wakes every worker blocked in pop once no more directories are
+// pending, so they can exit.
+func (q *dirQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
@@ -0,0 +1,104 @@
+package scan_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/pkg/scan"
+)
+
+// buildTree creates dirCount subdirectories directly under a temp root,
+// each holding filesPerDir one-byte files, and returns the root.
+func buildTree(t testing.TB, dirCount, filesPerDir int) string {
+	t.Helper()
+	root := t.TempDir()
+	for i := 0; i < dirCount; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("dir%d", i))
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+		for j := 0; j < filesPerDir; j++ {
+			path := filepath.Join(dir, fmt.Sprintf("file%d", j))
+			if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+				t.Fatalf("write %s: %v", path, err)
+			}
+		}
+	}
+	return root
+}
+
+// TestWalkWideFanOut guards against a self-deadlock when a single
+// directory fans out into more subdirectories than an internal queue's
+// buffer: if producers and consumers shared a bounded channel, every
+// worker could end up blocked trying to enqueue a new subdirectory while
+// nobody was left to drain it.
+func TestWalkWideFanOut(t *testing.T) {
+	const dirCount = 1030
+	root := buildTree(t, dirCount, 1)
+
+	items, err := scan.Walk(root, scan.Options{Concurrency: 1})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+
+	done := make(chan int)
+	go func() {
+		count := 0
+		for range items {
+			count++
+		}
+		done <- count
+	}()
+
+	select {
+	case count := <-done:
+		if count != dirCount {
+			t.Errorf("got %d files, want %d", count, dirCount)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Walk did not complete within 10s; likely deadlocked on a wide fan-out directory")
+	}
+}
+
+// BenchmarkWalk measures the bounded-concurrency worker pool this
+// package replaced the old one-goroutine-per-directory fan-out with.
+//
+// The tree here is 100x100 = 10,000 files rather than the 1M-file tree
+// the request envisioned: building and tearing down a tree that size on
+// every `go test` run would make the suite unusably slow for everyday
+// use. 10,000 files is enough to make the walker's own overhead (vs.
+// filepath.Walk's) visible; re-run with larger dirCount/filesPerDir
+// values locally when validating against NAS-scale trees.
+func BenchmarkWalk(b *testing.B) {
+	root := buildTree(b, 100, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		items, err := scan.Walk(root, scan.Options{})
+		if err != nil {
+			b.Fatalf("Walk: %v", err)
+		}
+		for range items {
+		}
+	}
+}
+
+// BenchmarkFilepathWalk is a sequential baseline: a plain filepath.Walk
+// that lists and stats every directory on a single goroutine. It does
+// not reproduce the old one-goroutine-per-directory fan-out.
+func BenchmarkFilepathWalk(b *testing.B) {
+	root := buildTree(b, 100, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := filepath.Walk(root, func(path string, f os.FileInfo, err error) error {
+			return err
+		})
+		if err != nil {
+			b.Fatalf("filepath.Walk: %v", err)
+		}
+	}
+}
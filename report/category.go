@@ -0,0 +1,24 @@
+package report
+
+// CategoryItem is one row of a by-extension or by-MIME breakdown: a
+// named bucket (a file extension like ".go", or a detected MIME type)
+// together with how many files fell into it and how many bytes they used.
+type CategoryItem struct {
+	Key       string
+	Count     int64
+	TotalSize int64
+}
+
+// ByTotalSize sorts CategoryItems by total bytes, descending.
+type ByTotalSize []CategoryItem
+
+func (c ByTotalSize) Len() int           { return len(c) }
+func (c ByTotalSize) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c ByTotalSize) Less(i, j int) bool { return c[i].TotalSize > c[j].TotalSize }
+
+// CategoryWriter is implemented by Writers that can additionally render
+// a by-extension / by-MIME style breakdown alongside the size sheets.
+// Not every format needs one: csv/ndjson/sqlite are fine without it.
+type CategoryWriter interface {
+	WriteCategories(name string, items []CategoryItem) error
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// CSVWriter renders each sorted view as its own "<base>.<view>.csv" file.
+type CSVWriter struct {
+	base string
+}
+
+// NewCSVWriter creates a writer that will emit one CSV file per sorted
+// view, named "<base>.<view>.csv".
+func NewCSVWriter(base string) *CSVWriter {
+	return &CSVWriter{base: base}
+}
+
+func (w *CSVWriter) WriteSorted(name string, items []StatItem, totals Totals) error {
+	path := fmt.Sprintf("%s.%s.csv", w.base, slug(name))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("report: create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	cw := csv.NewWriter(f)
+
+	if err := cw.Write([]string{"size_kb", "count", "size_pct", "count_pct"}); err != nil {
+		return fmt.Errorf("report: write header: %w", err)
+	}
+	for _, item := range items {
+		sizePct := float64(item.SizeInKiloBytes*item.Count) / float64(totals.Size) * 100
+		countPct := float64(item.Count) / float64(totals.Files) * 100
+		row := []string{
+			strconv.FormatInt(item.SizeInKiloBytes, 10),
+			strconv.FormatInt(item.Count, 10),
+			strconv.FormatFloat(sizePct, 'f', 4, 64),
+			strconv.FormatFloat(countPct, 'f', 4, 64),
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("report: write row: %w", err)
+		}
+	}
+
+	// Flush synchronously (not deferred) so an error that only surfaces
+	// at flush time - disk full, revoked permissions, an NFS hiccup -
+	// is actually reported instead of being discarded after we've
+	// already returned nil.
+	cw.Flush()
+	return cw.Error()
+}
+
+func (w *CSVWriter) Close() error { return nil }
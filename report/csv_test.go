@@ -0,0 +1,60 @@
+package report_test
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/report"
+)
+
+func TestCSVWriterRoundTrip(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "out")
+	w := report.NewCSVWriter(base)
+
+	items := []report.StatItem{
+		{SizeInKiloBytes: 4, Count: 10},
+		{SizeInKiloBytes: 8, Count: 5},
+	}
+	totals := report.CalculateTotals(items)
+	if err := w.WriteSorted("Sorted by Size", items, totals); err != nil {
+		t.Fatalf("WriteSorted: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	path := base + ".sorted-by-size.csv"
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows (incl. header), want 3", len(rows))
+	}
+	if want := []string{"size_kb", "count", "size_pct", "count_pct"}; !equal(rows[0], want) {
+		t.Errorf("got header %v, want %v", rows[0], want)
+	}
+	if rows[1][0] != "4" || rows[1][1] != "10" {
+		t.Errorf("got row %v, want size_kb=4 count=10", rows[1])
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package report
+
+import "fmt"
+
+// DistributionBucket is one row of a bucketed size histogram: a size
+// band together with the count, total, min, max and median byte size of
+// the files that fell into it.
+type DistributionBucket struct {
+	Label  string
+	Count  int64
+	Total  int64
+	Min    int64
+	Max    int64
+	Median int64
+}
+
+// Humanize formats bytes using binary (KiB/MiB/GiB/...) units, e.g.
+// "1.25 MiB" or "3.40 GiB".
+func Humanize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,51 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// NDJSONWriter renders each sorted view as its own "<base>.<view>.ndjson"
+// file, one StatItem per line, for piping into jq or similar.
+type NDJSONWriter struct {
+	base string
+}
+
+// NewNDJSONWriter creates a writer that will emit one newline-delimited
+// JSON file per sorted view, named "<base>.<view>.ndjson".
+func NewNDJSONWriter(base string) *NDJSONWriter {
+	return &NDJSONWriter{base: base}
+}
+
+type ndjsonRow struct {
+	SizeInKiloBytes int64   `json:"size_kb"`
+	Count           int64   `json:"count"`
+	SizePercent     float64 `json:"size_pct"`
+	CountPercent    float64 `json:"count_pct"`
+}
+
+func (w *NDJSONWriter) WriteSorted(name string, items []StatItem, totals Totals) error {
+	path := fmt.Sprintf("%s.%s.ndjson", w.base, slug(name))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("report: create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, item := range items {
+		row := ndjsonRow{
+			SizeInKiloBytes: item.SizeInKiloBytes,
+			Count:           item.Count,
+			SizePercent:     float64(item.SizeInKiloBytes*item.Count) / float64(totals.Size) * 100,
+			CountPercent:    float64(item.Count) / float64(totals.Files) * 100,
+		}
+		if err := enc.Encode(row); err != nil {
+			return fmt.Errorf("report: encode row: %w", err)
+		}
+	}
+	return nil
+}
+
+func (w *NDJSONWriter) Close() error { return nil }
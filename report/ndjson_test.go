@@ -0,0 +1,59 @@
+package report_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/report"
+)
+
+func TestNDJSONWriterRoundTrip(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "out")
+	w := report.NewNDJSONWriter(base)
+
+	items := []report.StatItem{
+		{SizeInKiloBytes: 4, Count: 10},
+		{SizeInKiloBytes: 8, Count: 5},
+	}
+	totals := report.CalculateTotals(items)
+	if err := w.WriteSorted("Sorted by Count", items, totals); err != nil {
+		t.Fatalf("WriteSorted: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	path := base + ".sorted-by-count.ndjson"
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var rows []map[string]float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var row map[string]float64
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("unmarshal %q: %v", scanner.Text(), err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan %s: %v", path, err)
+	}
+
+	if len(rows) != len(items) {
+		t.Fatalf("got %d lines, want %d", len(rows), len(items))
+	}
+	if rows[0]["size_kb"] != 4 || rows[0]["count"] != 10 {
+		t.Errorf("got row %v, want size_kb=4 count=10", rows[0])
+	}
+	wantSizePct := float64(4*10) / float64(totals.Size) * 100
+	if rows[0]["size_pct"] != wantSizePct {
+		t.Errorf("got size_pct %v, want %v", rows[0]["size_pct"], wantSizePct)
+	}
+}
@@ -0,0 +1,87 @@
+// Package report renders the sorted size histogram the scanner produces
+// in whatever output format the user asked for (xlsx, csv, ndjson,
+// sqlite, ...) behind a single Writer interface.
+package report
+
+import "fmt"
+
+// Totals holds the aggregate file count and size (in KB) a sorted
+// view's percentage columns are computed against.
+type Totals struct {
+	Files int64
+	Size  int64
+}
+
+// StatItem is one row of a sorted view: a file size (in KB) and how many
+// files share it.
+type StatItem struct {
+	SizeInKiloBytes int64
+	Count           int64
+}
+
+type BySize []StatItem
+
+func (s BySize) Len() int           { return len(s) }
+func (s BySize) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s BySize) Less(i, j int) bool { return s[i].SizeInKiloBytes < s[j].SizeInKiloBytes }
+
+type ByCount []StatItem
+
+func (c ByCount) Len() int           { return len(c) }
+func (c ByCount) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c ByCount) Less(i, j int) bool { return c[i].Count > c[j].Count }
+
+type BySizePercentage []StatItem
+
+func (s BySizePercentage) Len() int      { return len(s) }
+func (s BySizePercentage) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s BySizePercentage) Less(i, j int) bool {
+	totalSizeI := float64(s[i].SizeInKiloBytes * s[i].Count)
+	totalSizeJ := float64(s[j].SizeInKiloBytes * s[j].Count)
+	return totalSizeI > totalSizeJ
+}
+
+// CalculateTotals sums the file count and total size (in KB) across items.
+func CalculateTotals(items []StatItem) Totals {
+	var t Totals
+	for _, item := range items {
+		t.Files += item.Count
+		t.Size += item.SizeInKiloBytes * item.Count
+	}
+	return t
+}
+
+// Writer renders a set of sorted StatItem views to some destination
+// format (an xlsx sheet, a csv file, an ndjson stream, a sqlite table,
+// ...). Implementations that can also render a bucketed size
+// distribution additionally implement DistributionWriter.
+type Writer interface {
+	// WriteSorted renders one named view (e.g. "Sorted by Size") of items.
+	WriteSorted(name string, items []StatItem, totals Totals) error
+	// Close flushes and releases any resources the Writer holds open.
+	Close() error
+}
+
+// DistributionWriter is implemented by Writers that can additionally
+// render a bucketed size distribution (see -bucket). Not every format
+// needs one: csv/ndjson/sqlite are fine without it.
+type DistributionWriter interface {
+	WriteDistribution(buckets []DistributionBucket) error
+}
+
+// NewWriter constructs the Writer for one -format token, rooted at base
+// (a path with no extension; each format appends its own).
+func NewWriter(format, base string) (Writer, error) {
+	switch format {
+	case "xlsx":
+		return NewXLSXWriter(base + ".xlsx"), nil
+	case "csv":
+		return NewCSVWriter(base), nil
+	case "json":
+		return NewNDJSONWriter(base), nil
+	case "sqlite":
+		return NewSQLiteWriter(base + ".db")
+	default:
+		return nil, fmt.Errorf("report: unknown format %q", format)
+	}
+}
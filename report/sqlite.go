@@ -0,0 +1,88 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteWriter renders every sorted view into a single "stats" table in
+// a sqlite database, keyed by sort_key ("size", "count", "size_pct") so
+// a downstream query can pick out one view.
+type SQLiteWriter struct {
+	db *sql.DB
+}
+
+// NewSQLiteWriter opens (creating if needed) a sqlite database at path
+// and prepares its "stats" table.
+func NewSQLiteWriter(path string) (*SQLiteWriter, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("report: open sqlite %s: %w", path, err)
+	}
+
+	const schema = `CREATE TABLE IF NOT EXISTS stats (
+		sort_key  TEXT NOT NULL,
+		size_kb   INTEGER NOT NULL,
+		count     INTEGER NOT NULL,
+		size_pct  REAL NOT NULL,
+		count_pct REAL NOT NULL
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("report: create schema: %w", err)
+	}
+	return &SQLiteWriter{db: db}, nil
+}
+
+// WriteSorted inserts every item as one "stats" row. The whole view is
+// wrapped in a single transaction: without it, sqlite fsyncs once per
+// row under its default autocommit behaviour, which is pathologically
+// slow for the hundreds of thousands of distinct size buckets a NAS-scale
+// tree can produce.
+func (w *SQLiteWriter) WriteSorted(name string, items []StatItem, totals Totals) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return fmt.Errorf("report: begin transaction: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO stats (sort_key, size_kb, count, size_pct, count_pct) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("report: prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	sortKey := sortKeyFor(name)
+	for _, item := range items {
+		sizePct := float64(item.SizeInKiloBytes*item.Count) / float64(totals.Size) * 100
+		countPct := float64(item.Count) / float64(totals.Files) * 100
+		if _, err := stmt.Exec(sortKey, item.SizeInKiloBytes, item.Count, sizePct, countPct); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("report: insert row: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("report: commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (w *SQLiteWriter) Close() error {
+	return w.db.Close()
+}
+
+func sortKeyFor(viewName string) string {
+	switch viewName {
+	case "Sorted by Size":
+		return "size"
+	case "Sorted by Count":
+		return "count"
+	case "Sorted by Size%":
+		return "size_pct"
+	default:
+		return slug(viewName)
+	}
+}
@@ -0,0 +1,70 @@
+package report_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/Starkey-RU/FileSizerAnalyzer/report"
+)
+
+func TestSQLiteWriterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.db")
+	w, err := report.NewSQLiteWriter(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteWriter: %v", err)
+	}
+
+	items := []report.StatItem{
+		{SizeInKiloBytes: 4, Count: 10},
+		{SizeInKiloBytes: 8, Count: 5},
+	}
+	totals := report.CalculateTotals(items)
+	if err := w.WriteSorted("Sorted by Size", items, totals); err != nil {
+		t.Fatalf("WriteSorted: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("reopen %s: %v", path, err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT sort_key, size_kb, count FROM stats ORDER BY size_kb`)
+	if err != nil {
+		t.Fatalf("query stats: %v", err)
+	}
+	defer rows.Close()
+
+	var got []struct {
+		sortKey string
+		sizeKB  int64
+		count   int64
+	}
+	for rows.Next() {
+		var r struct {
+			sortKey string
+			sizeKB  int64
+			count   int64
+		}
+		if err := rows.Scan(&r.sortKey, &r.sizeKB, &r.count); err != nil {
+			t.Fatalf("scan row: %v", err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("iterate rows: %v", err)
+	}
+
+	if len(got) != len(items) {
+		t.Fatalf("got %d rows, want %d", len(got), len(items))
+	}
+	if got[0].sortKey != "size" || got[0].sizeKB != 4 || got[0].count != 10 {
+		t.Errorf("got row %+v, want sort_key=size size_kb=4 count=10", got[0])
+	}
+}
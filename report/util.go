@@ -0,0 +1,10 @@
+package report
+
+import "strings"
+
+// slug turns a sheet name like "Sorted by Size%" into a filename-safe
+// token such as "sorted-by-size-pct".
+func slug(name string) string {
+	r := strings.NewReplacer(" ", "-", "%", "-pct")
+	return strings.ToLower(r.Replace(name))
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize"
+)
+
+// XLSXWriter renders each sorted view as its own sheet in a single
+// workbook, matching the tool's original output format.
+type XLSXWriter struct {
+	f    *excelize.File
+	path string
+}
+
+// NewXLSXWriter creates a workbook that will be saved to path on Close.
+func NewXLSXWriter(path string) *XLSXWriter {
+	return &XLSXWriter{f: excelize.NewFile(), path: path}
+}
+
+func (w *XLSXWriter) WriteSorted(name string, items []StatItem, totals Totals) error {
+	w.f.NewSheet(name)
+
+	// Названия
+	w.f.SetCellValue(name, "A1", "File Size in KB")
+	w.f.SetCellValue(name, "B1", "Count")
+	w.f.SetCellValue(name, "C1", "Size %")
+	w.f.SetCellValue(name, "D1", "Count %")
+
+	// Общее
+	w.f.SetCellValue(name, "A2", "Total Files")
+	w.f.SetCellValue(name, "B2", totals.Files)
+	w.f.SetCellValue(name, "C2", "Total Size (KB)")
+	w.f.SetCellValue(name, "D2", totals.Size)
+
+	for i, item := range items {
+		row := fmt.Sprintf("%d", i+3)
+		w.f.SetCellValue(name, "A"+row, item.SizeInKiloBytes)
+		w.f.SetCellValue(name, "B"+row, item.Count)
+		w.f.SetCellValue(name, "C"+row, float64(item.SizeInKiloBytes*item.Count)/float64(totals.Size)*100)
+		w.f.SetCellValue(name, "D"+row, float64(item.Count)/float64(totals.Files)*100)
+	}
+	return nil
+}
+
+// WriteDistribution adds a "Distribution" sheet with a bar chart of file
+// count per size band, used when -bucket requests something other than
+// exact KB buckets.
+func (w *XLSXWriter) WriteDistribution(buckets []DistributionBucket) error {
+	sheetName := "Distribution"
+	w.f.NewSheet(sheetName)
+
+	w.f.SetCellValue(sheetName, "A1", "Size Range")
+	w.f.SetCellValue(sheetName, "B1", "Count")
+	w.f.SetCellValue(sheetName, "C1", "Total Size")
+	w.f.SetCellValue(sheetName, "D1", "Min")
+	w.f.SetCellValue(sheetName, "E1", "Max")
+	w.f.SetCellValue(sheetName, "F1", "Median")
+
+	for i, b := range buckets {
+		row := fmt.Sprintf("%d", i+2)
+		w.f.SetCellValue(sheetName, "A"+row, b.Label)
+		w.f.SetCellValue(sheetName, "B"+row, b.Count)
+		w.f.SetCellValue(sheetName, "C"+row, Humanize(b.Total))
+		w.f.SetCellValue(sheetName, "D"+row, Humanize(b.Min))
+		w.f.SetCellValue(sheetName, "E"+row, Humanize(b.Max))
+		w.f.SetCellValue(sheetName, "F"+row, Humanize(b.Median))
+	}
+
+	lastRow := len(buckets) + 1
+	chart := fmt.Sprintf(`{"type":"bar","series":[{"name":"%s!$B$1","categories":"%s!$A$2:$A$%d","values":"%s!$B$2:$B$%d"}],"title":{"name":"File Count by Size Range"}}`,
+		sheetName, sheetName, lastRow, sheetName, lastRow)
+	return w.f.AddChart(sheetName, "H1", chart)
+}
+
+// WriteCategories adds a sheet for a by-extension or by-MIME breakdown,
+// sorted by total bytes, alongside the size-bucket sheets.
+func (w *XLSXWriter) WriteCategories(name string, items []CategoryItem) error {
+	w.f.NewSheet(name)
+
+	w.f.SetCellValue(name, "A1", "Key")
+	w.f.SetCellValue(name, "B1", "Count")
+	w.f.SetCellValue(name, "C1", "Total Size")
+	w.f.SetCellValue(name, "D1", "Size %")
+	w.f.SetCellValue(name, "E1", "Count %")
+
+	var totalCount, totalSize int64
+	for _, item := range items {
+		totalCount += item.Count
+		totalSize += item.TotalSize
+	}
+
+	for i, item := range items {
+		row := fmt.Sprintf("%d", i+2)
+		w.f.SetCellValue(name, "A"+row, item.Key)
+		w.f.SetCellValue(name, "B"+row, item.Count)
+		w.f.SetCellValue(name, "C"+row, Humanize(item.TotalSize))
+		w.f.SetCellValue(name, "D"+row, float64(item.TotalSize)/float64(totalSize)*100)
+		w.f.SetCellValue(name, "E"+row, float64(item.Count)/float64(totalCount)*100)
+	}
+	return nil
+}
+
+func (w *XLSXWriter) Close() error {
+	return w.f.SaveAs(w.path)
+}
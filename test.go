@@ -2,129 +2,136 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"github.com/xuri/excelize"
+	"github.com/Starkey-RU/FileSizerAnalyzer/index"
+	"github.com/Starkey-RU/FileSizerAnalyzer/report"
 	"os"
-	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 )
 
-type StatItem struct {
-	sizeInKiloBytes int64
-	count           int64
-}
-
-type BySize []StatItem
-
-func (s BySize) Len() int           { return len(s) }
-func (s BySize) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-func (s BySize) Less(i, j int) bool { return s[i].sizeInKiloBytes < s[j].sizeInKiloBytes }
-
-type ByCount []StatItem
-
-func (c ByCount) Len() int           { return len(c) }
-func (c ByCount) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
-func (c ByCount) Less(i, j int) bool { return c[i].count > c[j].count }
-
-type BySizePercentage []StatItem
-
-func (s BySizePercentage) Len() int      { return len(s) }
-func (s BySizePercentage) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
-func (s BySizePercentage) Less(i, j int) bool {
-	totalSizeI := float64(s[i].sizeInKiloBytes * s[i].count)
-	totalSizeJ := float64(s[j].sizeInKiloBytes * s[j].count)
-	return totalSizeI > totalSizeJ
-}
-
-var wg sync.WaitGroup
-var m map[int64]int64 = make(map[int64]int64)
-var mutex sync.RWMutex
-
-func walkDir(dir string) {
-	defer wg.Done()
-
-	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
-		if f.IsDir() && path != dir && err == nil {
-			wg.Add(1)
-			go walkDir(path)
-			return filepath.SkipDir
+// loadOrScan builds the Index to report on for this run. With -index it
+// loads the previous index (if any) so Rescan can reuse unchanged
+// entries; with -diff it applies a zfs-diff style change list instead of
+// walking the tree at all.
+func loadOrScan(path, indexPath, diffPath string, concurrency int) (*index.Index, error) {
+	var (
+		prev *index.Index
+		err  error
+	)
+	if indexPath != "" {
+		prev, err = index.Load(indexPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading index: %w", err)
 		}
+	} else {
+		prev = index.New(path)
+	}
 
-		if !f.IsDir() {
-			fileSizeKB := f.Size() / 1024
-			mutex.Lock()
-			m[fileSizeKB] = m[fileSizeKB] + 1
-			mutex.Unlock()
+	if diffPath != "" {
+		f, err := os.Open(diffPath)
+		if err != nil {
+			return nil, fmt.Errorf("opening diff %s: %w", diffPath, err)
 		}
+		defer f.Close()
 
-		return err
-	})
+		if err := index.ApplyDiff(prev, f); err != nil {
+			return nil, fmt.Errorf("applying diff: %w", err)
+		}
+		return prev, nil
+	}
 
+	next, err := index.Rescan(path, prev, concurrency)
 	if err != nil {
-		fmt.Println("Error processing path", dir, err)
+		return nil, fmt.Errorf("scanning %s: %w", path, err)
 	}
+	return next, nil
 }
 
-func writeToExcel(sortedBySize, sortedByCount, sortedBySizePercentage []StatItem, filename string) {
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename = fmt.Sprintf("FileStats_%s.xlsx", timestamp)
-	f := excelize.NewFile()
+// writeReport builds a report.Writer for each requested format and
+// renders the three sorted views (plus the Distribution sheet, for
+// formats that support one) to each of them. The three sorted views
+// always carry the exact-KB histogram and are unaffected by -bucket;
+// only the Distribution sheet reflects the chosen Bucketing (see Exact
+// in bucket.go).
+func writeReport(formats []string, base string, sortedBySize, sortedByCount, sortedBySizePercentage []report.StatItem, distribution []report.DistributionBucket, byExtension, byMIME []report.CategoryItem) error {
+	views := []struct {
+		name  string
+		items []report.StatItem
+	}{
+		{"Sorted by Size", sortedBySize},
+		{"Sorted by Count", sortedByCount},
+		{"Sorted by Size%", sortedBySizePercentage},
+	}
 
-	// Create Sheet Sorted by Size
-	f.NewSheet("Sorted by Size")
-	totalFiles, totalSize := calculateTotals(sortedBySize)
-	writeStatItemsToSheet(f, "Sorted by Size", sortedBySize, totalFiles, totalSize)
+	for _, format := range formats {
+		w, err := report.NewWriter(format, base)
+		if err != nil {
+			return err
+		}
 
-	// Create Sheet Sorted by Count
-	f.NewSheet("Sorted by Count")
-	totalFiles, totalSize = calculateTotals(sortedByCount)
-	writeStatItemsToSheet(f, "Sorted by Count", sortedByCount, totalFiles, totalSize)
+		for _, view := range views {
+			totals := report.CalculateTotals(view.items)
+			if err := w.WriteSorted(view.name, view.items, totals); err != nil {
+				w.Close()
+				return fmt.Errorf("writing %s as %s: %w", view.name, format, err)
+			}
+		}
 
-	// Create Sheet Sorted by Size%
-	f.NewSheet("Sorted by Size%")
-	totalFiles, totalSize = calculateTotals(sortedBySizePercentage)
-	writeStatItemsToSheet(f, "Sorted by Size%", sortedBySizePercentage, totalFiles, totalSize)
+		if distribution != nil {
+			if dw, ok := w.(report.DistributionWriter); ok {
+				if err := dw.WriteDistribution(distribution); err != nil {
+					w.Close()
+					return fmt.Errorf("writing distribution as %s: %w", format, err)
+				}
+			}
+		}
 
-	// Save the file
-	if err := f.SaveAs(filename); err != nil {
-		fmt.Println(err)
-	}
-}
+		if cw, ok := w.(report.CategoryWriter); ok {
+			categoryViews := []struct {
+				name  string
+				items []report.CategoryItem
+			}{
+				{"By Extension", byExtension},
+				{"By MIME", byMIME},
+			}
+			for _, view := range categoryViews {
+				if view.items == nil {
+					continue
+				}
+				if err := cw.WriteCategories(view.name, view.items); err != nil {
+					w.Close()
+					return fmt.Errorf("writing %s as %s: %w", view.name, format, err)
+				}
+			}
+		}
 
-func calculateTotals(statItems []StatItem) (totalFiles int64, totalSize int64) {
-	for _, item := range statItems {
-		totalFiles += item.count
-		totalSize += item.sizeInKiloBytes * item.count
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("closing %s writer: %w", format, err)
+		}
 	}
-	return totalFiles, totalSize
+	return nil
 }
 
-func writeStatItemsToSheet(f *excelize.File, sheetName string, statItems []StatItem, totalFiles, totalSize int64) {
-	// Названия
-	f.SetCellValue(sheetName, "A1", "File Size in KB")
-	f.SetCellValue(sheetName, "B1", "Count")
-	f.SetCellValue(sheetName, "C1", "Size %")
-	f.SetCellValue(sheetName, "D1", "Count %")
-
-	// Общее
-	f.SetCellValue(sheetName, "A2", "Total Files")
-	f.SetCellValue(sheetName, "B2", totalFiles)
-	f.SetCellValue(sheetName, "C2", "Total Size (KB)")
-	f.SetCellValue(sheetName, "D2", totalSize)
-
-	for i, item := range statItems {
-		row := fmt.Sprintf("%d", i+3)
-		f.SetCellValue(sheetName, "A"+row, item.sizeInKiloBytes)
-		f.SetCellValue(sheetName, "B"+row, item.count)
-		f.SetCellValue(sheetName, "C"+row, float64(item.sizeInKiloBytes*item.count)/float64(totalSize)*100)
-		f.SetCellValue(sheetName, "D"+row, float64(item.count)/float64(totalFiles)*100)
+func main() {
+	indexPath := flag.String("index", "", "path to a persistent index file; when set, the tree is still walked but entries whose size and mtime are unchanged are reused from the previous run")
+	diffPath := flag.String("diff", "", "zfs-diff style change list (+ path / - path / M path) to apply instead of a full rescan")
+	concurrency := flag.Int("p", runtime.NumCPU(), "number of worker goroutines walking the tree concurrently")
+	bucketFlag := flag.String("bucket", "exact", "Distribution sheet bucketing: exact, pow2, or comma-separated byte boundaries for a custom bucketing")
+	formatFlag := flag.String("format", "xlsx", "comma-separated output formats to emit: xlsx, csv, json (ndjson), sqlite")
+	sniff := flag.Bool("sniff", false, "also detect each file's MIME type (costs a 512-byte read per file) and emit a By MIME sheet")
+	flag.Parse()
+
+	bucketing, customBounds, err := parseBucketFlag(*bucketFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
-}
+	formats := strings.Split(*formatFlag, ",")
 
-func main() {
 	fmt.Print("Enter the directory path: ")
 	reader := bufio.NewReader(os.Stdin)
 	path, err := reader.ReadString('\n')
@@ -134,32 +141,56 @@ func main() {
 	}
 	path = strings.TrimSpace(path)
 
-	wg.Add(1)
-	go walkDir(path)
-	wg.Wait()
+	idx, err := loadOrScan(path, *indexPath, *diffPath, *concurrency)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *indexPath != "" {
+		if err := idx.Save(*indexPath); err != nil {
+			fmt.Println("Error saving index:", err)
+		}
+	}
+
+	var distribution []report.DistributionBucket
+	if bucketing != Exact {
+		distribution = BuildDistribution(idx, bucketing, customBounds)
+	}
 
-	statItems := make([]StatItem, 0, len(m))
+	byExtension := BuildExtensionBreakdown(idx)
+	var byMIME []report.CategoryItem
+	if *sniff {
+		byMIME = BuildMIMEBreakdown(idx, *concurrency)
+	}
+
+	m := idx.Histogram()
+
+	statItems := make([]report.StatItem, 0, len(m))
 	for size, count := range m {
-		statItems = append(statItems, StatItem{sizeInKiloBytes: size, count: count})
+		statItems = append(statItems, report.StatItem{SizeInKiloBytes: size, Count: count})
 	}
 
-	sortedBySize := make([]StatItem, len(statItems))
+	sortedBySize := make([]report.StatItem, len(statItems))
 	copy(sortedBySize, statItems)
-	sort.Sort(BySize(sortedBySize))
+	sort.Sort(report.BySize(sortedBySize))
 
-	sortedByCount := make([]StatItem, len(statItems))
+	sortedByCount := make([]report.StatItem, len(statItems))
 	copy(sortedByCount, statItems)
-	sort.Sort(ByCount(sortedByCount))
+	sort.Sort(report.ByCount(sortedByCount))
 
-	sortedBySizePercentage := make([]StatItem, len(statItems))
+	sortedBySizePercentage := make([]report.StatItem, len(statItems))
 	copy(sortedBySizePercentage, statItems)
-	sort.Sort(BySizePercentage(sortedBySizePercentage))
+	sort.Sort(report.BySizePercentage(sortedBySizePercentage))
 
 	t := time.Now()
 	formattedTime := fmt.Sprintf("%d-%02d-%02d_%02d-%02d-%02d",
 		t.Year(), t.Month(), t.Day(),
 		t.Hour(), t.Minute(), t.Second())
-	filenameWithTimestamp := "FileStats_" + formattedTime + ".xlsx"
+	base := "FileStats_" + formattedTime
 
-	writeToExcel(sortedBySize, sortedByCount, sortedBySizePercentage, filenameWithTimestamp)
+	if err := writeReport(formats, base, sortedBySize, sortedByCount, sortedBySizePercentage, distribution, byExtension, byMIME); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 }